@@ -0,0 +1,185 @@
+package api
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tonkeeper/tongo/boc"
+	"github.com/tonkeeper/tongo/tlb"
+	walletTongo "github.com/tonkeeper/tongo/wallet"
+)
+
+// pubkeyExtractorCase pairs an extractor with a way to build a data cell it should be able
+// to read a known public key back out of. Unlike pubkeyFromCodeData's dispatch-by-code-hash
+// path - which would need a real mainnet code BOC per version to test end-to-end, and this
+// snapshot has no network access to pull one - each extractor only needs a data cell laid
+// out the way its tlb struct expects, which we can build ourselves and round-trip through
+// tlb.Marshal/Unmarshal.
+type pubkeyExtractorCase struct {
+	name    string
+	build   func(pubkey []byte) (*boc.Cell, error)
+	extract pubkeyExtractor
+}
+
+var pubkeyExtractorCases = []pubkeyExtractorCase{
+	{
+		name: "V3",
+		build: func(pubkey []byte) (*boc.Cell, error) {
+			var d walletTongo.DataV3
+			copy(d.PublicKey[:], pubkey)
+			cell := boc.NewCell()
+			if err := tlb.Marshal(cell, &d); err != nil {
+				return nil, err
+			}
+			return cell, nil
+		},
+		extract: extractPubkeyV3,
+	},
+	{
+		name: "V4",
+		build: func(pubkey []byte) (*boc.Cell, error) {
+			var d walletTongo.DataV4
+			copy(d.PublicKey[:], pubkey)
+			cell := boc.NewCell()
+			if err := tlb.Marshal(cell, &d); err != nil {
+				return nil, err
+			}
+			return cell, nil
+		},
+		extract: extractPubkeyV4,
+	},
+	{
+		name: "V5",
+		build: func(pubkey []byte) (*boc.Cell, error) {
+			var d walletTongo.DataV5
+			copy(d.PublicKey[:], pubkey)
+			cell := boc.NewCell()
+			if err := tlb.Marshal(cell, &d); err != nil {
+				return nil, err
+			}
+			return cell, nil
+		},
+		extract: extractPubkeyV5,
+	},
+	{
+		name: "HighloadV2",
+		build: func(pubkey []byte) (*boc.Cell, error) {
+			var d walletTongo.DataHighload
+			copy(d.PublicKey[:], pubkey)
+			cell := boc.NewCell()
+			if err := tlb.Marshal(cell, &d); err != nil {
+				return nil, err
+			}
+			return cell, nil
+		},
+		extract: extractPubkeyHighloadV2,
+	},
+	{
+		name: "HighloadV3",
+		build: func(pubkey []byte) (*boc.Cell, error) {
+			var d walletTongo.DataHighloadV3
+			copy(d.PublicKey[:], pubkey)
+			cell := boc.NewCell()
+			if err := tlb.Marshal(cell, &d); err != nil {
+				return nil, err
+			}
+			return cell, nil
+		},
+		extract: extractPubkeyHighloadV3,
+	},
+	{
+		name: "Lockup",
+		build: func(pubkey []byte) (*boc.Cell, error) {
+			var d walletTongo.DataLockup
+			copy(d.PublicKey[:], pubkey)
+			cell := boc.NewCell()
+			if err := tlb.Marshal(cell, &d); err != nil {
+				return nil, err
+			}
+			return cell, nil
+		},
+		extract: extractPubkeyLockup,
+	},
+	{
+		name: "Restricted",
+		build: func(pubkey []byte) (*boc.Cell, error) {
+			var d walletTongo.DataRestricted
+			copy(d.PublicKey[:], pubkey)
+			cell := boc.NewCell()
+			if err := tlb.Marshal(cell, &d); err != nil {
+				return nil, err
+			}
+			return cell, nil
+		},
+		extract: extractPubkeyRestricted,
+	},
+	{
+		// Exercises the fallback used for a version GetVerByCodeHash recognizes but that
+		// has no dedicated entry in pubkeyExtractors - see extractPubkeyStandardOffset.
+		name: "StandardOffsetFallback",
+		build: func(pubkey []byte) (*boc.Cell, error) {
+			cell := boc.NewCell()
+			if err := cell.WriteUint(0, standardWalletPubkeySkipBits); err != nil {
+				return nil, err
+			}
+			if err := cell.WriteBytes(pubkey); err != nil {
+				return nil, err
+			}
+			return cell, nil
+		},
+		extract: extractPubkeyStandardOffset,
+	},
+}
+
+func TestPubkeyExtractors(t *testing.T) {
+	for i, tc := range pubkeyExtractorCases {
+		tc := tc
+		want := make([]byte, 32)
+		for j := range want {
+			want[j] = byte(i*7 + j)
+		}
+		t.Run(tc.name, func(t *testing.T) {
+			cell, err := tc.build(want)
+			if err != nil {
+				t.Fatalf("building fixture cell: %v", err)
+			}
+			got, err := tc.extract(cell)
+			if err != nil {
+				t.Fatalf("extractor error: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("got %x, want %x", got, want)
+			}
+		})
+	}
+}
+
+// TestPubkeyFromCodeData_DispatchByRealCodeHash is the one the backlog actually asked for:
+// "one real account per supported version" exercised through pubkeyFromCodeData's real
+// entry point, GetVerByCodeHash(codeHash) -> pubkeyExtractors[ver]. TestPubkeyExtractors
+// above only calls each pubkeyExtractor directly against a hand-built data cell, so it never
+// exercises that dispatch at all - every version here still reads "not yet covered" rather
+// than "fixed", despite the "tests that actually run" commit message implying otherwise.
+// Closing this needs a real code BOC per version (V3R2, V4R1, V4R2, V5R1/beta, W5,
+// highload-v2/v3, lockup, restricted), which this offline snapshot has no way to fetch.
+//
+// TODO(chunk0-3): this t.Skip is the honest state of the requested test matrix - it is
+// intentionally left failing-visible (SKIP, not a silently-green pass) rather than deleted
+// or hidden, so CI output keeps surfacing the gap until real fixtures land.
+func TestPubkeyFromCodeData_DispatchByRealCodeHash(t *testing.T) {
+	t.Skip("not covered: needs a real mainnet code BOC per wallet version, unavailable in this offline snapshot")
+}
+
+func TestPubkeyFromCodeData_UnknownWalletVersion(t *testing.T) {
+	cell := boc.NewCell()
+	if err := cell.WriteUint(0, 32); err != nil {
+		t.Fatalf("building fixture cell: %v", err)
+	}
+	code, err := cell.ToBoc()
+	if err != nil {
+		t.Fatalf("serializing fixture cell: %v", err)
+	}
+	if _, err := pubkeyFromCodeData(code, code); err == nil {
+		t.Fatal("expected an error for an unrecognized code hash, got nil")
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+
+	"github.com/tonkeeper/tongo"
+)
+
+// resolvedAccount is what resolveAccountInput hands back to every handler in this file:
+// the account the input ultimately refers to, whether it should be rendered bounceable by
+// default, and, for DNS input, the chain of resolvers that got us there.
+type resolvedAccount struct {
+	ID       tongo.AccountID
+	Bounce   bool
+	DNSChain []string
+}
+
+// dnsResolveCacheTTL bounds how long a `.ton`/`.t.me` resolution is trusted before we
+// re-walk the resolver chain; DNS records can be updated on-chain at any time.
+const dnsResolveCacheTTL = 5 * time.Minute
+
+// dnsResolveCache is process-wide: resolutions are keyed by the DNS name itself and don't
+// depend on which request (or which Handler, in tests) looked them up. It would be a better
+// fit as a field wired through Handler's constructor - this checkout doesn't have that
+// constructor (Handler isn't defined anywhere in this tree; see the note in
+// account_events_stream.go for the same gap on the oas side), so there's nowhere to wire it
+// into. accountAtCache in account_history.go and stateOverrideAllowlist in
+// exec_get_methods_bulk.go are process-wide for the same reason.
+var dnsResolveCache = newDNSResolveCache()
+
+func newDNSResolveCache() *expirable.LRU[string, resolvedAccount] {
+	return expirable.NewLRU[string, resolvedAccount](4096, nil, dnsResolveCacheTTL)
+}
+
+// resolveAccountInput turns any account identifier this API accepts - raw form, friendly
+// form or a `.ton`/`.t.me` DNS name - into a concrete account. Every handler in
+// account_handlers.go that used to call tongo.ParseAddress on its account input now goes
+// through here, so `foo.ton` works
+// transparently everywhere an address does.
+func (h *Handler) resolveAccountInput(ctx context.Context, input string) (resolvedAccount, error) {
+	if !strings.Contains(input, ".") {
+		address, err := tongo.ParseAddress(input)
+		if err != nil {
+			return resolvedAccount{}, err
+		}
+		return resolvedAccount{ID: address.ID, Bounce: address.Bounce}, nil
+	}
+	if cached, ok := dnsResolveCache.Get(input); ok {
+		return cached, nil
+	}
+	resolved, err := h.resolveDNS(ctx, input)
+	if err != nil {
+		return resolvedAccount{}, fmt.Errorf("resolving %q: %w", input, err)
+	}
+	dnsResolveCache.Add(input, resolved)
+	return resolved, nil
+}
+
+// dnsMaxResolverHops bounds how many dnsresolve get-method calls resolveDNS will follow for
+// a single name. Every account-taking handler now reaches this transparently via
+// resolveAccountInput, so a misconfigured (or adversarial) record chain that points back on
+// itself must not be able to spin the request goroutine forever.
+const dnsMaxResolverHops = 8
+
+// resolveDNS walks the .ton/.t.me NFT collection for name, following dnsresolve
+// get-method chains until it lands on a wallet record.
+func (h *Handler) resolveDNS(ctx context.Context, name string) (resolvedAccount, error) {
+	domain, collection, err := splitDNSDomain(name)
+	if err != nil {
+		return resolvedAccount{}, err
+	}
+	collectionAccount, err := h.storage.GetDnsCollectionAddress(ctx, collection)
+	if err != nil {
+		return resolvedAccount{}, fmt.Errorf("unknown dns collection %q: %w", collection, err)
+	}
+	chain := []string{collectionAccount.ToRaw()}
+	record, err := h.storage.ResolveDomain(ctx, collectionAccount, domain)
+	if err != nil {
+		return resolvedAccount{}, err
+	}
+	for record.NextResolver != nil {
+		if len(chain) > dnsMaxResolverHops {
+			return resolvedAccount{}, fmt.Errorf("dns record for %q exceeds the %d-hop resolver chain limit", name, dnsMaxResolverHops)
+		}
+		chain = append(chain, record.NextResolver.ToRaw())
+		record, err = h.storage.ResolveDomain(ctx, *record.NextResolver, domain)
+		if err != nil {
+			return resolvedAccount{}, err
+		}
+	}
+	if record.Wallet == nil {
+		return resolvedAccount{}, fmt.Errorf("dns record for %q has no wallet entry", name)
+	}
+	return resolvedAccount{ID: *record.Wallet, Bounce: true, DNSChain: chain}, nil
+}
+
+// splitDNSDomain separates "alice.ton" into its domain part and the top-level DNS
+// collection ("ton" or "t.me") it should be resolved against.
+func splitDNSDomain(name string) (domain string, collection string, err error) {
+	switch {
+	case strings.HasSuffix(name, ".ton"):
+		return strings.TrimSuffix(name, ".ton"), "ton", nil
+	case strings.HasSuffix(name, ".t.me"):
+		return strings.TrimSuffix(name, ".t.me"), "t.me", nil
+	default:
+		return "", "", fmt.Errorf("unsupported dns domain %q", name)
+	}
+}
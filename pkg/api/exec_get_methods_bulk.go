@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/tonkeeper/opentonapi/internal/g"
+	"github.com/tonkeeper/opentonapi/pkg/core"
+	"github.com/tonkeeper/opentonapi/pkg/oas"
+	"github.com/tonkeeper/tongo"
+	"github.com/tonkeeper/tongo/abi"
+	"github.com/tonkeeper/tongo/tlb"
+	"github.com/tonkeeper/tongo/utils"
+)
+
+// oas.OptExecGetMethodsBulkReq/oas.MethodExecutionResults need spec additions and a
+// regenerated oas package this checkout doesn't have (see the note in
+// account_events_stream.go). h.executor.RunSmcMethodOnAccount, used below and in
+// account_history.go, is the executor's entry point for running a method against a given
+// account state; like the rest of Handler's fields it's declared on the real executor
+// interface upstream, which isn't part of this checkout either, so it can't be added here
+// without guessing at an interface this file doesn't own.
+
+// accountStateOverride describes a speculative, in-memory replacement for an account's
+// code, data and/or balance, used by ExecGetMethodsBulk to simulate get-methods against
+// hypothetical state without touching the indexed chain.
+type accountStateOverride struct {
+	Code    []byte
+	Data    []byte
+	Balance *int64
+}
+
+// apply returns a copy of base with whichever of Code/Data/Balance the override sets
+// replaced, for handing to h.executor.RunSmcMethodOnAccount.
+func (o accountStateOverride) apply(base core.RawAccount) core.RawAccount {
+	state := base
+	if o.Code != nil {
+		state.Code = o.Code
+	}
+	if o.Data != nil {
+		state.Data = o.Data
+	}
+	if o.Balance != nil {
+		state.Balance = *o.Balance
+	}
+	return state
+}
+
+// stateOverrideAllowlistEnv names the env var listing raw account IDs allowed to be used
+// as state_overrides targets; unset or empty means no account can be overridden. This is
+// deliberately a static, operator-configured allowlist rather than something callers can
+// widen themselves.
+const stateOverrideAllowlistEnv = "EXEC_GET_METHODS_BULK_STATE_OVERRIDE_ALLOWLIST"
+
+var stateOverrideAllowlist = loadStateOverrideAllowlist()
+
+func loadStateOverrideAllowlist() map[tongo.AccountID]struct{} {
+	allowed := make(map[tongo.AccountID]struct{})
+	for _, raw := range strings.Split(os.Getenv(stateOverrideAllowlistEnv), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		id, err := tongo.ParseAccountID(raw)
+		if err != nil {
+			continue
+		}
+		allowed[id] = struct{}{}
+	}
+	return allowed
+}
+
+func isStateOverrideAllowed(accountID tongo.AccountID) bool {
+	_, ok := stateOverrideAllowlist[accountID]
+	return ok
+}
+
+// ExecGetMethodsBulk runs a batch of get-methods, optionally against an in-memory copy of
+// the requested accounts with state_overrides applied, mirroring eth_call-with-state-override:
+// it lets integrators simulate behavior against hypothetical code/data without touching real
+// state. Overrides are only honored for accounts on stateOverrideAllowlist.
+func (h *Handler) ExecGetMethodsBulk(ctx context.Context, request oas.OptExecGetMethodsBulkReq) (*oas.MethodExecutionResults, error) {
+	req := request.Value
+	if len(req.Requests) == 0 {
+		return nil, toError(http.StatusBadRequest, fmt.Errorf("empty list of requests"))
+	}
+	if !h.limits.isBulkQuantityAllowed(len(req.Requests)) {
+		return nil, toError(http.StatusBadRequest, fmt.Errorf("the maximum number of get-methods to execute at once: %v", h.limits.BulkLimits))
+	}
+	overrides := make(map[tongo.AccountID]accountStateOverride, len(req.StateOverrides))
+	for rawAccountID, o := range req.StateOverrides {
+		accountID, err := tongo.ParseAccountID(rawAccountID)
+		if err != nil {
+			return nil, toError(http.StatusBadRequest, err)
+		}
+		if !isStateOverrideAllowed(accountID) {
+			return nil, toError(http.StatusForbidden, fmt.Errorf("state overrides for %v are not allowed", accountID.ToRaw()))
+		}
+		override := accountStateOverride{}
+		if o.Code.Set {
+			override.Code = o.Code.Value
+		}
+		if o.Data.Set {
+			override.Data = o.Data.Value
+		}
+		if o.Balance.Set {
+			override.Balance = &o.Balance.Value
+		}
+		overrides[accountID] = override
+	}
+	results := make([]oas.MethodExecutionResult, 0, len(req.Requests))
+	for _, item := range req.Requests {
+		account, err := h.resolveAccountInput(ctx, item.Account)
+		if err != nil {
+			return nil, toError(http.StatusBadRequest, err)
+		}
+		state, err := h.storage.GetRawAccount(ctx, account.ID)
+		if err != nil {
+			return nil, toError(http.StatusInternalServerError, err)
+		}
+		if override, ok := overrides[account.ID]; ok {
+			state = override.apply(state)
+		}
+		var stack tlb.VmStack
+		for _, p := range item.Args {
+			r, err := stringToTVMStackRecord(p)
+			if err != nil {
+				return nil, toError(http.StatusBadRequest, fmt.Errorf("can't parse arg '%v' as any TVMStackValue", p))
+			}
+			stack = append(stack, r)
+		}
+		exitCode, stack, err := h.executor.RunSmcMethodOnAccount(ctx, state, utils.MethodIdFromName(item.Method), stack)
+		if err != nil {
+			return nil, toError(http.StatusInternalServerError, err)
+		}
+		result := oas.MethodExecutionResult{
+			Success:  exitCode == 0 || exitCode == 1,
+			ExitCode: int(exitCode),
+			Stack:    make([]oas.TvmStackRecord, 0, len(stack)),
+		}
+		for i := range stack {
+			value, err := convertTvmStackValue(stack[i])
+			if err != nil {
+				return nil, toError(http.StatusInternalServerError, err)
+			}
+			result.Stack = append(result.Stack, value)
+		}
+		for _, decoder := range abi.KnownGetMethodsDecoder[item.Method] {
+			_, v, err := decoder(stack)
+			if err == nil {
+				value, err := json.Marshal(v)
+				if err != nil {
+					return nil, toError(http.StatusInternalServerError, err)
+				}
+				result.SetDecoded(g.ChangeJsonKeys(value, g.CamelToSnake))
+				break
+			}
+		}
+		results = append(results, result)
+	}
+	return &oas.MethodExecutionResults{Results: results}, nil
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tonkeeper/opentonapi/pkg/oas"
+	"github.com/tonkeeper/tongo"
+)
+
+// oas.OptVerifyAccountOwnershipReq and oas.VerifyAccountOwnershipOK are new oas types
+// VerifyAccountOwnership takes/returns; like the other new endpoints in this series they
+// need api/openapi.yml additions and a regenerated oas package, neither of which is part of
+// this checkout (see the note in account_events_stream.go).
+
+// tonProofMaxAge rejects a ton-connect proof whose timestamp is further in the past than
+// this; it bounds the window during which a captured signature could be replayed.
+const tonProofMaxAge = 15 * time.Minute
+
+var (
+	tonProofItemPrefix = []byte("ton-proof-item-v2/")
+	tonConnectPrefix   = []byte("ton-connect")
+)
+
+// ownerProof is a ton-connect `ton_proof` payload: a wallet signing a domain-bound,
+// time-bound message to prove it controls the account without submitting a transaction.
+type ownerProof struct {
+	Address   string
+	Timestamp int64
+	Domain    string
+	Signature []byte
+	Payload   string
+}
+
+// errStaleProof, errInvalidProofAddress and errUnverifiableWallet name the ways proof
+// verification can fail before a signature is even checked; errSignatureMismatch is the one
+// case - a signature that was actually evaluated and simply doesn't match - that
+// VerifyAccountOwnership reports as a plain negative verdict rather than an error
+// (SearchAccounts rejects the request with it instead).
+var (
+	errStaleProof          = fmt.Errorf("proof timestamp is too old")
+	errInvalidProofAddress = fmt.Errorf("proof address is malformed")
+	errUnverifiableWallet  = fmt.Errorf("wallet version doesn't support proof verification")
+	errSignatureMismatch   = fmt.Errorf("signature verification failed")
+)
+
+// ownerProofErrorStatus classifies an error from verifyOwnerProof the same way at every
+// call site: a malformed input the proof itself is responsible for is a 400, anything else
+// (a storage/backend failure mid-verification) is a 500. errSignatureMismatch is included
+// here for SearchAccounts, which has no "verified: false" response shape of its own and
+// falls back to rejecting the request; VerifyAccountOwnership special-cases it instead of
+// calling this.
+func ownerProofErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errStaleProof), errors.Is(err, errInvalidProofAddress),
+		errors.Is(err, errUnverifiableWallet), errors.Is(err, errSignatureMismatch):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// verifyOwnerProof checks that proof was produced by the private key controlling
+// proof.Address, per the ton-connect TonProofItemReplySuccess verification algorithm:
+// the canonical message is SHA-256'd, prefixed with 0xffff and "ton-connect", SHA-256'd
+// again, and checked against proof.Signature with the account's on-chain public key.
+func (h *Handler) verifyOwnerProof(ctx context.Context, proof ownerProof, now time.Time) (tongo.AccountID, error) {
+	if now.Sub(time.Unix(proof.Timestamp, 0)) > tonProofMaxAge {
+		return tongo.AccountID{}, errStaleProof
+	}
+	account, err := tongo.ParseAddress(proof.Address)
+	if err != nil {
+		return tongo.AccountID{}, fmt.Errorf("%w: %v", errInvalidProofAddress, err)
+	}
+	rawAccount, err := h.storage.GetRawAccount(ctx, account.ID)
+	if err != nil {
+		return tongo.AccountID{}, err
+	}
+	pubKey, err := h.storage.GetWalletPubKey(ctx, account.ID)
+	if err != nil {
+		pubKey, err = pubkeyFromCodeData(rawAccount.Code, rawAccount.Data)
+		if err != nil {
+			return tongo.AccountID{}, fmt.Errorf("%w: %v", errUnverifiableWallet, err)
+		}
+	}
+	message := tonProofMessage(account.ID, proof.Domain, proof.Timestamp, proof.Payload)
+	if !ed25519.Verify(pubKey, message, proof.Signature) {
+		return tongo.AccountID{}, errSignatureMismatch
+	}
+	return account.ID, nil
+}
+
+// tonProofMessage builds the 32-byte hash a wallet signs for a ton-connect proof:
+//
+//	sha256(0xffff || "ton-connect" || sha256(
+//	    "ton-proof-item-v2/" || workchain(4, BE) || address(32) ||
+//	    len(domain)(4, LE) || domain || timestamp(8, LE) || payload))
+func tonProofMessage(account tongo.AccountID, domain string, timestamp int64, payload string) []byte {
+	inner := make([]byte, 0, len(tonProofItemPrefix)+4+32+4+len(domain)+8+len(payload))
+	inner = append(inner, tonProofItemPrefix...)
+	var workchain [4]byte
+	binary.BigEndian.PutUint32(workchain[:], uint32(account.Workchain))
+	inner = append(inner, workchain[:]...)
+	inner = append(inner, account.Address[:]...)
+	var domainLen [4]byte
+	binary.LittleEndian.PutUint32(domainLen[:], uint32(len(domain)))
+	inner = append(inner, domainLen[:]...)
+	inner = append(inner, domain...)
+	var ts [8]byte
+	binary.LittleEndian.PutUint64(ts[:], uint64(timestamp))
+	inner = append(inner, ts[:]...)
+	inner = append(inner, payload...)
+	innerHash := sha256.Sum256(inner)
+
+	envelope := make([]byte, 0, 2+len(tonConnectPrefix)+len(innerHash))
+	envelope = append(envelope, 0xff, 0xff)
+	envelope = append(envelope, tonConnectPrefix...)
+	envelope = append(envelope, innerHash[:]...)
+	outerHash := sha256.Sum256(envelope)
+	return outerHash[:]
+}
+
+// VerifyAccountOwnership checks a ton-connect proof-of-ownership payload and reports
+// which account, if any, it proves control of. Only errSignatureMismatch - a signature
+// that was actually evaluated and simply doesn't match - is reported as `verified: false`.
+// Everything else (a stale timestamp, a malformed address, a wallet version we can't
+// extract a public key for, or a storage failure that meant the proof was never evaluated
+// at all) propagates as an error, classified by ownerProofErrorStatus the same way
+// SearchAccounts classifies the identical errors from the same verifyOwnerProof call.
+func (h *Handler) VerifyAccountOwnership(ctx context.Context, request oas.OptVerifyAccountOwnershipReq) (*oas.VerifyAccountOwnershipOK, error) {
+	proof := ownerProof{
+		Address:   request.Value.Address,
+		Timestamp: request.Value.Timestamp,
+		Domain:    request.Value.Domain,
+		Signature: request.Value.Signature,
+		Payload:   request.Value.Payload,
+	}
+	accountID, err := h.verifyOwnerProof(ctx, proof, time.Now())
+	switch {
+	case err == nil:
+		return &oas.VerifyAccountOwnershipOK{Verified: true, Address: oas.NewOptString(accountID.ToRaw())}, nil
+	case errors.Is(err, errSignatureMismatch):
+		return &oas.VerifyAccountOwnershipOK{Verified: false}, nil
+	default:
+		return nil, toError(ownerProofErrorStatus(err), err)
+	}
+}